@@ -0,0 +1,523 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/BurntSushi/toml"
+	"github.com/urfave/cli/v2"
+
+	bvhttp "github.com/digideskio/budget-verifier/pkg/http"
+	"github.com/digideskio/budget-verifier/pkg/verifier"
+)
+
+func main() {
+	if err := buildApp().Run(os.Args); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// sharedFlags are the flags every subcommand needs to locate its inputs.
+// They're attached to both the app itself (so `budget-verifier --bank=...
+// verify` works) and to each subcommand (so `budget-verifier verify
+// --bank=...` works too) -- urfave/cli v2 rejects a flag given after a
+// subcommand unless that subcommand declares it itself. Because of that
+// duplication, reading one of these back from a command's Action must go
+// through flagString/flagBool/flagInt below rather than ctx.String & co
+// directly: the subcommand's own (usually unset) copy of the flag would
+// otherwise shadow the value --config or a global flag set on the parent.
+func sharedFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{Name: "bank", Usage: "path to the bank statement export"},
+		&cli.StringFlag{Name: "budget", Usage: "path to the budget app export"},
+		&cli.StringFlag{Name: "bank-format", Value: "auto", Usage: "bank statement format driver to use (or \"auto\" to detect)"},
+		&cli.StringFlag{Name: "budget-format", Value: "auto", Usage: "budget export format driver to use (or \"auto\" to detect)"},
+		&cli.StringFlag{Name: "filter-file", Value: verifier.FilterFileName, Usage: "path to the filter file"},
+		&cli.IntFlag{Name: "date-range-days", Value: 7, Usage: "a budget entry must be dated on or before a bank entry, by fewer than this many days, to count as its match"},
+		&cli.BoolFlag{Name: "verbose", Usage: "log every comparison decision, not just the summary"},
+		&cli.StringFlag{Name: "output", Value: "text", Usage: "output format: text, csv, json, or ndjson"},
+	}
+}
+
+func buildApp() *cli.App {
+	flags := append([]cli.Flag{
+		&cli.StringFlag{Name: "config", Usage: "path to a TOML config file providing defaults for the flags below"},
+	}, sharedFlags()...)
+
+	return &cli.App{
+		Name:   "budget-verifier",
+		Usage:  "reconcile a bank statement export against a budget app export",
+		Flags:  flags,
+		Before: loadConfigDefaults,
+		Commands: []*cli.Command{
+			verifyCommand(),
+			filterCommand(),
+			explainCommand(),
+			serveCommand(),
+		},
+	}
+}
+
+// tomlConfig mirrors sharedFlags for --config files. Any field left at its
+// zero value is ignored, so a config file only needs to set the flags it
+// wants to override.
+type tomlConfig struct {
+	Bank          string `toml:"bank"`
+	Budget        string `toml:"budget"`
+	BankFormat    string `toml:"bank_format"`
+	BudgetFormat  string `toml:"budget_format"`
+	FilterFile    string `toml:"filter_file"`
+	DateRangeDays int    `toml:"date_range_days"`
+	Verbose       bool   `toml:"verbose"`
+	Output        string `toml:"output"`
+}
+
+// loadConfigDefaults runs before every command. If --config is given, it
+// fills in any flag the user didn't pass explicitly on the command line.
+func loadConfigDefaults(ctx *cli.Context) error {
+	configPath := ctx.String("config")
+	if configPath == "" {
+		return nil
+	}
+
+	var cfg tomlConfig
+	if _, err := toml.DecodeFile(configPath, &cfg); err != nil {
+		return fmt.Errorf("failed to read config %s: %+v", configPath, err)
+	}
+
+	setStringDefault(ctx, "bank", cfg.Bank)
+	setStringDefault(ctx, "budget", cfg.Budget)
+	setStringDefault(ctx, "bank-format", cfg.BankFormat)
+	setStringDefault(ctx, "budget-format", cfg.BudgetFormat)
+	setStringDefault(ctx, "filter-file", cfg.FilterFile)
+	setStringDefault(ctx, "output", cfg.Output)
+
+	if !ctx.IsSet("date-range-days") && cfg.DateRangeDays != 0 {
+		if err := ctx.Set("date-range-days", strconv.Itoa(cfg.DateRangeDays)); err != nil {
+			return fmt.Errorf("failed to apply config date_range_days: %+v", err)
+		}
+	}
+	if !ctx.IsSet("verbose") && cfg.Verbose {
+		if err := ctx.Set("verbose", "true"); err != nil {
+			return fmt.Errorf("failed to apply config verbose: %+v", err)
+		}
+	}
+
+	return nil
+}
+
+func setStringDefault(ctx *cli.Context, flagName, value string) error {
+	if value == "" || ctx.IsSet(flagName) {
+		return nil
+	}
+
+	return ctx.Set(flagName, value)
+}
+
+// flagString, flagBool, and flagInt read a flag declared by both the App
+// and the current command (see sharedFlags) from whichever context in the
+// lineage actually has it set -- the current command's own copy if the
+// user passed it there, otherwise walking up to the app-level context a
+// global flag or --config populated. Falls back to the current context's
+// value (its default) if no context in the lineage has it set.
+func flagString(ctx *cli.Context, name string) string {
+	for _, c := range ctx.Lineage() {
+		if c.IsSet(name) {
+			return c.String(name)
+		}
+	}
+
+	return ctx.String(name)
+}
+
+func flagBool(ctx *cli.Context, name string) bool {
+	for _, c := range ctx.Lineage() {
+		if c.IsSet(name) {
+			return c.Bool(name)
+		}
+	}
+
+	return ctx.Bool(name)
+}
+
+func flagInt(ctx *cli.Context, name string) int {
+	for _, c := range ctx.Lineage() {
+		if c.IsSet(name) {
+			return c.Int(name)
+		}
+	}
+
+	return ctx.Int(name)
+}
+
+// optionsFromContext builds verifier.Options out of the shared flags.
+func optionsFromContext(ctx *cli.Context) verifier.Options {
+	return verifier.Options{
+		DateMatchRangeDays: flagInt(ctx, "date-range-days"),
+		Verbose:            flagBool(ctx, "verbose"),
+	}
+}
+
+// diagf prints a diagnostic message to stderr, leaving stdout free for
+// --output=json/ndjson/csv.
+func diagf(format string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, format+"\n", args...)
+}
+
+// newReporter picks the verifier.Reporter that matches --output, and a finish
+// func to call once CompareTransactions returns: ndjson and text stream as
+// they go, so finish is a no-op; json instead collects every event and
+// prints them as a single array.
+func newReporter(output string, verbose bool) (verifier.Reporter, func() error) {
+	switch output {
+	case "ndjson":
+		return verifier.NDJSONReporter{W: os.Stdout}, func() error { return nil }
+
+	case "json":
+		collector := &verifier.CollectingReporter{}
+		finish := func() error {
+			buf, err := json.MarshalIndent(collector.Events, "", "  ")
+			if err != nil {
+				return fmt.Errorf("failed to marshal events: %+v", err)
+			}
+			fmt.Println(string(buf))
+			return nil
+		}
+		return collector, finish
+
+	default:
+		return verifier.TextReporter{W: os.Stderr, Verbose: verbose}, func() error { return nil }
+	}
+}
+
+func verifyCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "verify",
+		Usage: "compare a bank statement export to a budget app export and report missing transactions",
+		Flags: append([]cli.Flag{
+			&cli.StringFlag{Name: "dump-vector", Usage: "write a testdata/vectors-style regression vector from these inputs to the given directory"},
+			&cli.BoolFlag{Name: "dry-run-filters", Usage: "don't compare against the budget export, just print which filter rule (if any) matches each bank transaction"},
+		}, sharedFlags()...),
+		Action: runVerify,
+	}
+}
+
+func runVerify(ctx *cli.Context) error {
+	bankPath := flagString(ctx, "bank")
+	if bankPath == "" {
+		return cli.Exit("--bank is required", 1)
+	}
+
+	bankTransactions, err := verifier.LoadBankTransactions(bankPath, flagString(ctx, "bank-format"))
+	if err != nil {
+		return err
+	}
+
+	filters, err := verifier.LoadFilters(flagString(ctx, "filter-file"))
+	if err != nil {
+		return err
+	}
+
+	filterChain, err := verifier.CompileFilters(filters)
+	if err != nil {
+		return fmt.Errorf("failed to compile %s: %+v", flagString(ctx, "filter-file"), err)
+	}
+
+	if ctx.Bool("dry-run-filters") {
+		return dryRunFilters(bankTransactions, filterChain)
+	}
+
+	budgetPath := flagString(ctx, "budget")
+	if budgetPath == "" {
+		return cli.Exit("--bank and --budget are both required", 1)
+	}
+
+	diagf("comparing bank statement %s to budget entries %s", bankPath, budgetPath)
+
+	budgetTransactions, err := verifier.LoadBudgetTransactions(budgetPath, flagString(ctx, "budget-format"))
+	if err != nil {
+		return err
+	}
+
+	output := flagString(ctx, "output")
+	reporter, finish := newReporter(output, flagBool(ctx, "verbose"))
+
+	missingTransactions, err := verifier.CompareTransactions(bankTransactions, budgetTransactions, filterChain, optionsFromContext(ctx), reporter)
+	if err != nil {
+		return fmt.Errorf("failed to compare transactions for %s and %s: %+v", bankPath, budgetPath, err)
+	}
+
+	if dir := ctx.String("dump-vector"); dir != "" {
+		if err := dumpVector(dir, bankPath, budgetPath, flagString(ctx, "filter-file"), bankTransactions, budgetTransactions, missingTransactions); err != nil {
+			return fmt.Errorf("failed to dump vector to %s: %+v", dir, err)
+		}
+	}
+
+	if err := finish(); err != nil {
+		return err
+	}
+
+	return reportMissing(output, missingTransactions)
+}
+
+// dryRunFilters prints, for every bank transaction, which filter rule (if
+// any) last matched it and whether that rule excludes or includes it,
+// without loading a budget export or running the matching engine at all.
+func dryRunFilters(bankTransactions []verifier.Transaction, filterChain verifier.FilterChain) error {
+	for _, t := range bankTransactions {
+		excluded, matched := filterChain.Select(&t)
+		switch {
+		case excluded:
+			fmt.Printf("excluded by %s: %s\n", matched.String(), t.StringNoFollow())
+		case matched != nil:
+			fmt.Printf("included by %s: %s\n", matched.String(), t.StringNoFollow())
+		default:
+			fmt.Printf("no rule matched: %s\n", t.StringNoFollow())
+		}
+	}
+
+	return nil
+}
+
+// dumpVector captures a testdata/vectors-style regression vector from a real
+// verify run: the bank and budget exports as given, the filters that were
+// applied, and the resulting match graph.
+func dumpVector(dir, bankPath, budgetPath, filterPath string, bankTransactions, budgetTransactions, missing []verifier.Transaction) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %+v", dir, err)
+	}
+
+	if err := copyFile(bankPath, filepath.Join(dir, "bank.csv")); err != nil {
+		return err
+	}
+	if err := copyFile(budgetPath, filepath.Join(dir, "budget.csv")); err != nil {
+		return err
+	}
+
+	filters, err := verifier.TryLoadFilters(filterPath)
+	if err != nil {
+		return err
+	}
+	filterBuf, err := json.MarshalIndent(filters, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal filters: %+v", err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "filter.json"), filterBuf, 0644); err != nil {
+		return fmt.Errorf("failed to write filter.json: %+v", err)
+	}
+
+	expectation := verifier.BuildVectorExpectation(bankTransactions, budgetTransactions, missing)
+	expectedBuf, err := json.MarshalIndent(expectation, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal expected.json: %+v", err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "expected.json"), expectedBuf, 0644); err != nil {
+		return fmt.Errorf("failed to write expected.json: %+v", err)
+	}
+
+	return nil
+}
+
+func copyFile(src, dst string) error {
+	buf, err := ioutil.ReadFile(src)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %+v", src, err)
+	}
+
+	if err := ioutil.WriteFile(dst, buf, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %+v", dst, err)
+	}
+
+	return nil
+}
+
+// reportMissing prints the final summary once CompareTransactions has run.
+// For json and ndjson, the reporter installed by newReporter already put the
+// full structured picture on stdout, so there's nothing left to add here.
+func reportMissing(output string, missing []verifier.Transaction) error {
+	switch output {
+	case "json", "ndjson":
+		return nil
+
+	case "csv":
+		w := csv.NewWriter(os.Stdout)
+		if err := w.Write([]string{"id", "date", "description", "details", "amount"}); err != nil {
+			return err
+		}
+		for _, t := range missing {
+			row := []string{t.ID(), t.Timestamp.Format("2006-01-02"), t.Description, t.Details, verifier.FormatAmount(t.Amount)}
+			if err := w.Write(row); err != nil {
+				return err
+			}
+		}
+		w.Flush()
+		return w.Error()
+
+	default:
+		if len(missing) == 0 {
+			fmt.Println("There are no missing transactions.  Good job budgeter!")
+			return nil
+		}
+
+		fmt.Printf("There are %d missing transactions.\n", len(missing))
+	}
+
+	return nil
+}
+
+func filterCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "filter",
+		Usage: "manage entries in the filter file",
+		Subcommands: []*cli.Command{
+			{
+				Name:  "add",
+				Usage: "append a filter",
+				Flags: append([]cli.Flag{
+					&cli.StringFlag{Name: "regex", Usage: "regex matched against a transaction"},
+					&cli.StringFlag{Name: "field", Value: "description", Usage: "field regex is matched against: description, details, or either"},
+					&cli.StringFlag{Name: "action", Value: "exclude", Usage: "exclude (the default) or include, to whitelist past an earlier exclude rule"},
+					&cli.IntFlag{Name: "min", Usage: "minimum amount in cents"},
+					&cli.IntFlag{Name: "max", Usage: "maximum amount in cents"},
+					&cli.StringFlag{Name: "date-before", Usage: "only match transactions before this date (2006-01-02)"},
+					&cli.StringFlag{Name: "date-after", Usage: "only match transactions after this date (2006-01-02)"},
+				}, sharedFlags()...),
+				Action: runFilterAdd,
+			},
+			{
+				Name:   "list",
+				Usage:  "print every filter",
+				Flags:  sharedFlags(),
+				Action: runFilterList,
+			},
+		},
+	}
+}
+
+func runFilterAdd(ctx *cli.Context) error {
+	filterPath := flagString(ctx, "filter-file")
+
+	filters, err := verifier.TryLoadFilters(filterPath)
+	if err != nil {
+		return err
+	}
+
+	filter := verifier.Filter{
+		FilterRegex: ctx.String("regex"),
+		Field:       ctx.String("field"),
+		Action:      ctx.String("action"),
+		MinAmount:   ctx.Int("min"),
+		MaxAmount:   ctx.Int("max"),
+		DateBefore:  ctx.String("date-before"),
+		DateAfter:   ctx.String("date-after"),
+	}
+
+	if _, err := verifier.CompileFilters([]verifier.Filter{filter}); err != nil {
+		return fmt.Errorf("invalid filter: %+v", err)
+	}
+
+	filters = append(filters, filter)
+
+	return verifier.SaveFilters(filterPath, filters)
+}
+
+func runFilterList(ctx *cli.Context) error {
+	filters, err := verifier.TryLoadFilters(flagString(ctx, "filter-file"))
+	if err != nil {
+		return err
+	}
+
+	for _, f := range filters {
+		fmt.Println(f.String())
+	}
+
+	return nil
+}
+
+func explainCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "explain",
+		Usage:     "show the match status of a single transaction",
+		ArgsUsage: "<txid>",
+		Flags:     sharedFlags(),
+		Action:    runExplain,
+	}
+}
+
+func runExplain(ctx *cli.Context) error {
+	txid := ctx.Args().First()
+	if txid == "" {
+		return cli.Exit("explain requires a transaction ID (see the \"id\" column from --output=csv)", 1)
+	}
+
+	bankPath := flagString(ctx, "bank")
+	budgetPath := flagString(ctx, "budget")
+	if bankPath == "" || budgetPath == "" {
+		return cli.Exit("--bank and --budget are both required", 1)
+	}
+
+	bankTransactions, err := verifier.LoadBankTransactions(bankPath, flagString(ctx, "bank-format"))
+	if err != nil {
+		return err
+	}
+
+	budgetTransactions, err := verifier.LoadBudgetTransactions(budgetPath, flagString(ctx, "budget-format"))
+	if err != nil {
+		return err
+	}
+
+	filters, err := verifier.LoadFilters(flagString(ctx, "filter-file"))
+	if err != nil {
+		return err
+	}
+
+	filterChain, err := verifier.CompileFilters(filters)
+	if err != nil {
+		return fmt.Errorf("failed to compile %s: %+v", flagString(ctx, "filter-file"), err)
+	}
+
+	if _, err := verifier.CompareTransactions(bankTransactions, budgetTransactions, filterChain, optionsFromContext(ctx), nil); err != nil {
+		return err
+	}
+
+	for _, t := range bankTransactions {
+		if t.ID() == txid {
+			fmt.Println(t.String())
+			return nil
+		}
+	}
+	for _, t := range budgetTransactions {
+		if t.ID() == txid {
+			fmt.Println(t.String())
+			return nil
+		}
+	}
+
+	return cli.Exit(fmt.Sprintf("no transaction found with ID %s", txid), 1)
+}
+
+func serveCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "serve",
+		Usage: "expose the verifier as a REST service",
+		Flags: append([]cli.Flag{
+			&cli.StringFlag{Name: "addr", Value: ":8080", Usage: "address to listen on"},
+		}, sharedFlags()...),
+		Action: runServe,
+	}
+}
+
+func runServe(ctx *cli.Context) error {
+	server := bvhttp.NewServer(flagString(ctx, "filter-file"), optionsFromContext(ctx))
+
+	addr := ctx.String("addr")
+	log.Printf("listening on %s", addr)
+	return http.ListenAndServe(addr, server.Handler())
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestConfigFileSuppliesSharedFlags exercises --config end-to-end: a TOML
+// config file sets --bank, and the value must reach runVerify's Action even
+// though verify redeclares its own (unset) copy of --bank. This is the
+// regression covered by flagString: without it, the subcommand's own flag
+// shadows the one --config populated on the app-level context.
+func TestConfigFileSuppliesSharedFlags(t *testing.T) {
+	dir := t.TempDir()
+
+	configPath := filepath.Join(dir, "config.toml")
+	configBody := "bank = \"pkg/verifier/testdata/vectors/exact_same_day/bank.csv\"\n"
+	if err := os.WriteFile(configPath, []byte(configBody), 0644); err != nil {
+		t.Fatalf("failed to write config: %+v", err)
+	}
+
+	filterPath := filepath.Join(dir, "filter.json")
+	if err := os.WriteFile(filterPath, []byte("[]"), 0644); err != nil {
+		t.Fatalf("failed to write filter.json: %+v", err)
+	}
+
+	out := captureStdout(t, func() {
+		err := buildApp().Run([]string{
+			"budget-verifier",
+			"--config", configPath,
+			"verify",
+			"--budget", "pkg/verifier/testdata/vectors/exact_same_day/budget.csv",
+			"--filter-file", filterPath,
+		})
+		if err != nil {
+			t.Fatalf("verify failed: %+v", err)
+		}
+	})
+
+	if !strings.Contains(out, "no missing transactions") {
+		t.Errorf("expected a successful match, got: %s", out)
+	}
+}
+
+// TestGlobalFlagBeforeSubcommand is the other half of the same regression:
+// a shared flag given before the subcommand name (so it's parsed against
+// the app-level flag set, same as --config populating it) must still reach
+// the subcommand's Action.
+func TestGlobalFlagBeforeSubcommand(t *testing.T) {
+	dir := t.TempDir()
+
+	filterPath := filepath.Join(dir, "filter.json")
+	if err := os.WriteFile(filterPath, []byte("[]"), 0644); err != nil {
+		t.Fatalf("failed to write filter.json: %+v", err)
+	}
+
+	out := captureStdout(t, func() {
+		err := buildApp().Run([]string{
+			"budget-verifier",
+			"--bank", "pkg/verifier/testdata/vectors/exact_same_day/bank.csv",
+			"--budget", "pkg/verifier/testdata/vectors/exact_same_day/budget.csv",
+			"--filter-file", filterPath,
+			"verify",
+		})
+		if err != nil {
+			t.Fatalf("verify failed: %+v", err)
+		}
+	})
+
+	if !strings.Contains(out, "no missing transactions") {
+		t.Errorf("expected a successful match, got: %s", out)
+	}
+}
+
+// captureStdout redirects os.Stdout for the duration of fn and returns what
+// was written to it.
+func captureStdout(t *testing.T, fn func()) string {
+	t.Helper()
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("failed to create pipe: %+v", err)
+	}
+
+	original := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = original }()
+
+	fn()
+
+	if err := w.Close(); err != nil {
+		t.Fatalf("failed to close pipe: %+v", err)
+	}
+
+	buf, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("failed to read captured stdout: %+v", err)
+	}
+
+	return string(buf)
+}
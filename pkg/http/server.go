@@ -0,0 +1,258 @@
+// Package http exposes budget-verifier's matching engine as a small REST
+// service, in the style of moneygo's /transaction/ handlers: upload bank and
+// budget exports, manage filters, and run a verify pass, all over HTTP so a
+// web UI or scheduled job can drive the tool without shelling out.
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/digideskio/budget-verifier/pkg/verifier"
+)
+
+// Server holds the state a running instance of the REST API needs: where to
+// persist filters and which matching Options to compare with.
+type Server struct {
+	FilterPath string
+	Opts       verifier.Options
+}
+
+// NewServer returns a Server persisting filters to filterPath.
+func NewServer(filterPath string, opts verifier.Options) *Server {
+	return &Server{FilterPath: filterPath, Opts: opts}
+}
+
+// Handler returns the http.Handler serving every route this package knows
+// about, ready to be passed to http.ListenAndServe.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/transactions/bank", s.handleTransactionsBank)
+	mux.HandleFunc("/transactions/budget", s.handleTransactionsBudget)
+	mux.HandleFunc("/filters", s.handleFilters)
+	mux.HandleFunc("/filters/", s.handleFilter)
+	mux.HandleFunc("/verify", s.handleVerify)
+	return mux
+}
+
+func (s *Server) handleTransactionsBank(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	transactions, err := verifier.ParseBankCSV(r.Body, r.URL.Query().Get("format"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	writeJSON(w, transactionResponses(transactions))
+}
+
+func (s *Server) handleTransactionsBudget(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	transactions, err := verifier.ParseBudgetCSV(r.Body, r.URL.Query().Get("format"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	writeJSON(w, transactionResponses(transactions))
+}
+
+func (s *Server) handleFilters(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		filters, err := verifier.TryLoadFilters(s.FilterPath)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		writeJSON(w, filterResponses(filters))
+
+	case http.MethodPost:
+		var f verifier.Filter
+		if err := json.NewDecoder(r.Body).Decode(&f); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		filters, err := verifier.TryLoadFilters(s.FilterPath)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		filters = append(filters, f)
+		if err := verifier.SaveFilters(s.FilterPath, filters); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.WriteHeader(http.StatusCreated)
+		writeJSON(w, newFilterResponse(f))
+
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *Server) handleFilter(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id := strings.TrimPrefix(r.URL.Path, "/filters/")
+	if id == "" {
+		http.Error(w, "missing filter id", http.StatusBadRequest)
+		return
+	}
+
+	filters, err := verifier.TryLoadFilters(s.FilterPath)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	kept := filters[:0]
+	found := false
+	for _, f := range filters {
+		if f.ID() == id {
+			found = true
+			continue
+		}
+		kept = append(kept, f)
+	}
+
+	if !found {
+		http.Error(w, "filter not found", http.StatusNotFound)
+		return
+	}
+
+	if err := verifier.SaveFilters(s.FilterPath, kept); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// transactionResponse is how a Transaction is rendered over HTTP. Transaction
+// has no JSON-visible id of its own (ID() is a Go method, not a tagged
+// field), so without this a client uploading via /transactions/bank or
+// /transactions/budget would have no way to learn the id /verify's
+// verifyResult reports matches by. Field names (besides id) intentionally
+// match what encoding/json's case-insensitive fallback already accepts for a
+// bare, tag-less verifier.Transaction, so a transactionResponse can be fed
+// straight back into /verify's "bank"/"budget" arrays.
+type transactionResponse struct {
+	ID          string    `json:"id"`
+	Timestamp   time.Time `json:"timestamp"`
+	Description string    `json:"description"`
+	Details     string    `json:"details"`
+	Amount      int       `json:"amount"`
+}
+
+func newTransactionResponse(t verifier.Transaction) transactionResponse {
+	return transactionResponse{
+		ID:          t.ID(),
+		Timestamp:   t.Timestamp,
+		Description: t.Description,
+		Details:     t.Details,
+		Amount:      t.Amount,
+	}
+}
+
+func transactionResponses(transactions []verifier.Transaction) []transactionResponse {
+	out := make([]transactionResponse, len(transactions))
+	for i, t := range transactions {
+		out[i] = newTransactionResponse(t)
+	}
+	return out
+}
+
+// filterResponse is Filter plus the id DELETE /filters/{id} expects, for the
+// same reason transactionResponse exists: Filter.ID() is a Go method the
+// JSON encoding of a bare Filter never surfaces.
+type filterResponse struct {
+	verifier.Filter
+	ID string `json:"id"`
+}
+
+func newFilterResponse(f verifier.Filter) filterResponse {
+	return filterResponse{Filter: f, ID: f.ID()}
+}
+
+func filterResponses(filters []verifier.Filter) []filterResponse {
+	out := make([]filterResponse, len(filters))
+	for i, f := range filters {
+		out[i] = newFilterResponse(f)
+	}
+	return out
+}
+
+type verifyRequest struct {
+	Bank   []verifier.Transaction `json:"bank"`
+	Budget []verifier.Transaction `json:"budget"`
+}
+
+type verifyResult struct {
+	Matched   map[string]string `json:"matched"`   // bank transaction id -> matched budget transaction id
+	Unmatched []string          `json:"unmatched"` // bank transaction ids with no match
+}
+
+func (s *Server) handleVerify(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req verifyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	filters, err := verifier.TryLoadFilters(s.FilterPath)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	filterChain, err := verifier.CompileFilters(filters)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if _, err := verifier.CompareTransactions(req.Bank, req.Budget, filterChain, s.Opts, nil); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	result := verifyResult{Matched: map[string]string{}, Unmatched: []string{}}
+	for _, t := range req.Bank {
+		if t.Matching != nil {
+			result.Matched[t.ID()] = t.Matching.ID()
+		} else {
+			result.Unmatched = append(result.Unmatched, t.ID())
+		}
+	}
+
+	writeJSON(w, result)
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,141 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/digideskio/budget-verifier/pkg/verifier"
+)
+
+// TestFilterCRUD exercises POST/GET/DELETE /filters end-to-end, including
+// that the id a client needs for DELETE is actually the one POST/GET hand
+// back (previously unreachable: Filter.ID() never appeared in the JSON).
+func TestFilterCRUD(t *testing.T) {
+	handler := NewServer(filepath.Join(t.TempDir(), "filter.json"), verifier.DefaultOptions()).Handler()
+
+	created := postFilter(t, handler, verifier.Filter{FilterRegex: "rent", Action: "exclude"})
+	if created.ID == "" {
+		t.Fatal("POST /filters returned an empty id")
+	}
+
+	listed := getFilters(t, handler)
+	if len(listed) != 1 {
+		t.Fatalf("GET /filters returned %d filters, want 1", len(listed))
+	}
+	if listed[0].ID != created.ID {
+		t.Errorf("GET /filters id = %q, want %q", listed[0].ID, created.ID)
+	}
+
+	deleteReq := httptest.NewRequest(http.MethodDelete, "/filters/"+created.ID, nil)
+	deleteRec := httptest.NewRecorder()
+	handler.ServeHTTP(deleteRec, deleteReq)
+	if deleteRec.Code != http.StatusNoContent {
+		t.Fatalf("DELETE /filters/%s = %d, want %d: %s", created.ID, deleteRec.Code, http.StatusNoContent, deleteRec.Body.String())
+	}
+
+	listed = getFilters(t, handler)
+	if len(listed) != 0 {
+		t.Fatalf("GET /filters after delete returned %d filters, want 0", len(listed))
+	}
+}
+
+// TestVerifyMatchedAndUnmatched covers /verify's matched/unmatched split,
+// confirming it's keyed by the same ids /transactions/bank and
+// /transactions/budget hand back to the client.
+func TestVerifyMatchedAndUnmatched(t *testing.T) {
+	handler := NewServer(filepath.Join(t.TempDir(), "filter.json"), verifier.DefaultOptions()).Handler()
+
+	bank := uploadTransactions(t, handler, "/transactions/bank",
+		"Date,Description,Amount,Balance\n,,,\n01/05/2024,Coffee Shop,-4.50,100.00\n01/06/2024,Unmatched Charge,-9.00,91.00\n")
+	budget := uploadTransactions(t, handler, "/transactions/budget",
+		"Date,Num,Payee,Memo,Amount\n01/05/2024,1001,Coffee Shop,,-4.50\n")
+
+	body, err := json.Marshal(map[string]interface{}{"bank": bank, "budget": budget})
+	if err != nil {
+		t.Fatalf("failed to marshal verify request: %+v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/verify", bytes.NewReader(body))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("POST /verify = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+
+	var result verifyResult
+	if err := json.Unmarshal(rec.Body.Bytes(), &result); err != nil {
+		t.Fatalf("failed to unmarshal verify response: %+v", err)
+	}
+
+	if budgetID, ok := result.Matched[bank[0].ID]; !ok || budgetID != budget[0].ID {
+		t.Errorf("Matched[%s] = (%q, %v), want (%q, true)", bank[0].ID, budgetID, ok, budget[0].ID)
+	}
+
+	if len(result.Unmatched) != 1 || result.Unmatched[0] != bank[1].ID {
+		t.Errorf("Unmatched = %v, want [%s]", result.Unmatched, bank[1].ID)
+	}
+}
+
+func postFilter(t *testing.T, handler http.Handler, f verifier.Filter) filterResponse {
+	t.Helper()
+
+	body, err := json.Marshal(f)
+	if err != nil {
+		t.Fatalf("failed to marshal filter: %+v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/filters", bytes.NewReader(body))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("POST /filters = %d, want %d: %s", rec.Code, http.StatusCreated, rec.Body.String())
+	}
+
+	var created filterResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &created); err != nil {
+		t.Fatalf("failed to unmarshal filter response: %+v", err)
+	}
+
+	return created
+}
+
+func getFilters(t *testing.T, handler http.Handler) []filterResponse {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodGet, "/filters", nil)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET /filters = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+
+	var filters []filterResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &filters); err != nil {
+		t.Fatalf("failed to unmarshal filters response: %+v", err)
+	}
+
+	return filters
+}
+
+func uploadTransactions(t *testing.T, handler http.Handler, path, csv string) []transactionResponse {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(csv))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("POST %s = %d, want %d: %s", path, rec.Code, http.StatusOK, rec.Body.String())
+	}
+
+	var transactions []transactionResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &transactions); err != nil {
+		t.Fatalf("failed to unmarshal transactions response: %+v", err)
+	}
+
+	return transactions
+}
@@ -0,0 +1,51 @@
+package verifier
+
+// VectorMatch records that the bank transaction at BankIndex (its 0-based
+// index in Parse order) matched the budget transaction at BudgetIndex.
+type VectorMatch struct {
+	BankIndex   int `json:"bank_index"`
+	BudgetIndex int `json:"budget_index"`
+}
+
+// VectorExpectation is the schema testdata/vectors/*/expected.json files
+// decode into: which bank transactions matched which budget transactions,
+// and which bank transactions came up missing. Indices refer to the order
+// LoadBankTransactions/LoadBudgetTransactions returned them in; filtered
+// transactions appear in neither list.
+type VectorExpectation struct {
+	Matches            []VectorMatch `json:"matches"`
+	MissingBankIndices []int         `json:"missing_bank_indices"`
+}
+
+// BuildVectorExpectation derives a VectorExpectation from the result of
+// CompareTransactions. Used both by the conformance test runner, to compare
+// against a recorded vector, and by `verify --dump-vector`, to record one.
+func BuildVectorExpectation(bankTransactions, budgetTransactions, missing []Transaction) VectorExpectation {
+	missingIDs := map[string]bool{}
+	for _, t := range missing {
+		missingIDs[t.ID()] = true
+	}
+
+	budgetIndexByID := map[string]int{}
+	for i, t := range budgetTransactions {
+		budgetIndexByID[t.ID()] = i
+	}
+
+	expectation := VectorExpectation{Matches: []VectorMatch{}, MissingBankIndices: []int{}}
+	for i, t := range bankTransactions {
+		if missingIDs[t.ID()] {
+			expectation.MissingBankIndices = append(expectation.MissingBankIndices, i)
+			continue
+		}
+
+		if t.Matching == nil {
+			continue
+		}
+
+		if budgetIndex, ok := budgetIndexByID[t.Matching.ID()]; ok {
+			expectation.Matches = append(expectation.Matches, VectorMatch{BankIndex: i, BudgetIndex: budgetIndex})
+		}
+	}
+
+	return expectation
+}
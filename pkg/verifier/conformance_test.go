@@ -0,0 +1,83 @@
+package verifier_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/digideskio/budget-verifier/pkg/verifier"
+)
+
+// TestConformanceVectors walks testdata/vectors, running each one through the
+// library API and diffing the resulting match graph against its
+// expected.json. See testdata/vectors/README.md for the directory layout.
+func TestConformanceVectors(t *testing.T) {
+	root := "testdata/vectors"
+
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		t.Fatalf("failed to read %s: %+v", root, err)
+	}
+
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+
+		name := entry.Name()
+		t.Run(name, func(t *testing.T) {
+			dir := filepath.Join(root, name)
+
+			bankTransactions, err := verifier.LoadBankTransactions(filepath.Join(dir, "bank.csv"), "auto")
+			if err != nil {
+				t.Fatalf("failed to load bank.csv: %+v", err)
+			}
+
+			budgetTransactions, err := verifier.LoadBudgetTransactions(filepath.Join(dir, "budget.csv"), "auto")
+			if err != nil {
+				t.Fatalf("failed to load budget.csv: %+v", err)
+			}
+
+			filters, err := verifier.TryLoadFilters(filepath.Join(dir, "filter.json"))
+			if err != nil {
+				t.Fatalf("failed to load filter.json: %+v", err)
+			}
+
+			filterChain, err := verifier.CompileFilters(filters)
+			if err != nil {
+				t.Fatalf("failed to compile filter.json: %+v", err)
+			}
+
+			var want verifier.VectorExpectation
+			buf, err := os.ReadFile(filepath.Join(dir, "expected.json"))
+			if err != nil {
+				t.Fatalf("failed to read expected.json: %+v", err)
+			}
+			if err := json.Unmarshal(buf, &want); err != nil {
+				t.Fatalf("failed to unmarshal expected.json: %+v", err)
+			}
+
+			missing, err := verifier.CompareTransactions(bankTransactions, budgetTransactions, filterChain, verifier.DefaultOptions(), nil)
+			if err != nil {
+				t.Fatalf("CompareTransactions failed: %+v", err)
+			}
+
+			got := verifier.BuildVectorExpectation(bankTransactions, budgetTransactions, missing)
+
+			sortExpectation(&got)
+			sortExpectation(&want)
+
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("match graph mismatch:\n got: %+v\nwant: %+v", got, want)
+			}
+		})
+	}
+}
+
+func sortExpectation(e *verifier.VectorExpectation) {
+	sort.Slice(e.Matches, func(i, j int) bool { return e.Matches[i].BankIndex < e.Matches[j].BankIndex })
+	sort.Ints(e.MissingBankIndices)
+}
@@ -0,0 +1,84 @@
+package verifier
+
+import (
+	"errors"
+	"fmt"
+)
+
+// BankDriver knows how to recognize and parse one particular bank's CSV (or
+// other) export format. Drivers are registered at init time with
+// RegisterBankDriver and selected either explicitly via --bank-format or by
+// probing each registered driver's Detect method in registration order.
+type BankDriver interface {
+	// Name identifies the driver for --bank-format and log messages.
+	Name() string
+	// Detect reports whether records look like this driver's format.
+	Detect(records [][]string) bool
+	// Parse converts records into Transactions.
+	Parse(records [][]string) ([]Transaction, error)
+}
+
+// BudgetDriver knows how to recognize and parse one particular budgeting
+// app's export format. See BankDriver.
+type BudgetDriver interface {
+	Name() string
+	Detect(records [][]string) bool
+	Parse(records [][]string) ([]Transaction, error)
+}
+
+var bankDrivers []BankDriver
+var budgetDrivers []BudgetDriver
+
+// RegisterBankDriver adds d to the set of bank drivers considered by
+// --bank-format=auto. Drivers are tried in registration order, so more
+// specific drivers should register before looser ones.
+func RegisterBankDriver(d BankDriver) {
+	bankDrivers = append(bankDrivers, d)
+}
+
+// RegisterBudgetDriver adds d to the set of budget drivers considered by
+// --budget-format=auto.
+func RegisterBudgetDriver(d BudgetDriver) {
+	budgetDrivers = append(budgetDrivers, d)
+}
+
+// findBankDriver resolves name ("auto" or empty to probe every registered
+// driver) against records and returns the driver to parse them with.
+func findBankDriver(name string, records [][]string) (BankDriver, error) {
+	if name != "" && name != "auto" {
+		for _, d := range bankDrivers {
+			if d.Name() == name {
+				return d, nil
+			}
+		}
+		return nil, fmt.Errorf("unknown bank format %q", name)
+	}
+
+	for _, d := range bankDrivers {
+		if d.Detect(records) {
+			return d, nil
+		}
+	}
+
+	return nil, errors.New("failed to detect bank statement format, try passing --bank-format explicitly")
+}
+
+// findBudgetDriver is findBankDriver's counterpart for budget exports.
+func findBudgetDriver(name string, records [][]string) (BudgetDriver, error) {
+	if name != "" && name != "auto" {
+		for _, d := range budgetDrivers {
+			if d.Name() == name {
+				return d, nil
+			}
+		}
+		return nil, fmt.Errorf("unknown budget format %q", name)
+	}
+
+	for _, d := range budgetDrivers {
+		if d.Detect(records) {
+			return d, nil
+		}
+	}
+
+	return nil, errors.New("failed to detect budget export format, try passing --budget-format explicitly")
+}
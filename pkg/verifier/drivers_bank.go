@@ -0,0 +1,207 @@
+package verifier
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func init() {
+	RegisterBankDriver(genericBankDriver{})
+	RegisterBankDriver(chaseBankDriver{})
+	RegisterBankDriver(ofxBankDriver{})
+}
+
+// genericBankDriver is the original bank CSV layout this tool was built
+// around: a "Date,Description,Amount" header row, one throwaway row after
+// it, then data.
+type genericBankDriver struct{}
+
+func (genericBankDriver) Name() string { return "generic" }
+
+func (genericBankDriver) Detect(records [][]string) bool {
+	return genericBankHeaderRow(records) >= 0
+}
+
+func (genericBankDriver) Parse(records [][]string) ([]Transaction, error) {
+	start := genericBankHeaderRow(records)
+	if start < 0 {
+		return nil, errors.New("failed to find start of useful records")
+	}
+
+	transactions := []Transaction{}
+	for i := start; i < len(records); i++ {
+		transaction, err := parseTransaction(records[i], 0, 1, 2, -1)
+		if err != nil {
+			log.Printf("invalid record, skipping: %+v", err)
+			continue
+		}
+
+		transactions = append(transactions, transaction)
+	}
+
+	return transactions, nil
+}
+
+// genericBankHeaderRow returns the index of the first data row, or -1 if the
+// "Date,Description,Amount" header can't be found.
+func genericBankHeaderRow(records [][]string) int {
+	start := -1
+	for i, record := range records {
+		if len(record) > 3 && record[0] == "Date" && record[1] == "Description" && record[2] == "Amount" {
+			// found the headers that precedes the useful records.  there's still 1 more useless record,
+			// so the good starting point is actually 1 greater than just the next index.
+			start = i + 2
+		}
+	}
+
+	return start
+}
+
+// chaseBankDriver reads the CSV export Chase (and several other banks using
+// the same layout) produce: "Details,Posting Date,Description,Amount,Type,Balance,Check or Slip #".
+type chaseBankDriver struct{}
+
+func (chaseBankDriver) Name() string { return "chase" }
+
+func (chaseBankDriver) Detect(records [][]string) bool {
+	if len(records) == 0 {
+		return false
+	}
+
+	header := records[0]
+	return len(header) >= 4 &&
+		header[0] == "Details" &&
+		header[1] == "Posting Date" &&
+		header[2] == "Description" &&
+		header[3] == "Amount"
+}
+
+func (chaseBankDriver) Parse(records [][]string) ([]Transaction, error) {
+	transactions := []Transaction{}
+	for i := 1; i < len(records); i++ {
+		transaction, err := parseTransaction(records[i], 1, 2, 3, 0)
+		if err != nil {
+			log.Printf("invalid record, skipping: %+v", err)
+			continue
+		}
+
+		transactions = append(transactions, transaction)
+	}
+
+	return transactions, nil
+}
+
+// ofxBankDriver reads OFX/QFX exports. readFile feeds every export through
+// encoding/csv, which doesn't understand OFX's SGML, so records here are
+// mostly single-field lines; Parse rejoins them and scans for <STMTTRN>
+// blocks instead of relying on column positions.
+type ofxBankDriver struct{}
+
+func (ofxBankDriver) Name() string { return "ofx" }
+
+func (ofxBankDriver) Detect(records [][]string) bool {
+	for i, record := range records {
+		if i > 10 {
+			break
+		}
+		if strings.Contains(strings.Join(record, ","), "OFXHEADER") {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (ofxBankDriver) Parse(records [][]string) ([]Transaction, error) {
+	lines := make([]string, len(records))
+	for i, record := range records {
+		lines[i] = strings.Join(record, ",")
+	}
+	body := strings.Join(lines, "\n")
+
+	transactions := []Transaction{}
+	for _, block := range ofxTag(body, "STMTTRN") {
+		amountStr := ofxValue(block, "TRNAMT")
+		dateStr := ofxValue(block, "DTPOSTED")
+		description := ofxValue(block, "NAME")
+		details := ofxValue(block, "MEMO")
+
+		if amountStr == "" || dateStr == "" {
+			log.Printf("invalid OFX transaction, skipping: %s", block)
+			continue
+		}
+
+		amount, err := strconv.ParseFloat(amountStr, 64)
+		if err != nil {
+			log.Printf("invalid OFX amount, skipping: %+v", err)
+			continue
+		}
+
+		// OFX dates are YYYYMMDD, optionally followed by a time and timezone suffix.
+		if len(dateStr) > 8 {
+			dateStr = dateStr[:8]
+		}
+		timestamp, err := time.Parse("20060102", dateStr)
+		if err != nil {
+			log.Printf("invalid OFX date, skipping: %+v", err)
+			continue
+		}
+
+		transactions = append(transactions, Transaction{
+			Timestamp:   timestamp,
+			Description: description,
+			Details:     details,
+			Amount:      int(amount * 100),
+		})
+	}
+
+	return transactions, nil
+}
+
+// ofxTag returns the contents of every <tag>...</tag> block found in body. A
+// <tag> with no closing tag yields everything after it to the end of body.
+func ofxTag(body, tag string) []string {
+	open := fmt.Sprintf("<%s>", tag)
+	closeTag := fmt.Sprintf("</%s>", tag)
+
+	var blocks []string
+	for {
+		start := strings.Index(body, open)
+		if start < 0 {
+			break
+		}
+		body = body[start+len(open):]
+
+		end := strings.Index(body, closeTag)
+		if end < 0 {
+			blocks = append(blocks, body)
+			break
+		}
+
+		blocks = append(blocks, body[:end])
+		body = body[end+len(closeTag):]
+	}
+
+	return blocks
+}
+
+// ofxValue returns the value of an SGML tag within block, terminated either
+// by a closing tag or the next line.
+func ofxValue(block, tag string) string {
+	open := fmt.Sprintf("<%s>", tag)
+	start := strings.Index(block, open)
+	if start < 0 {
+		return ""
+	}
+
+	rest := block[start+len(open):]
+	if end := strings.IndexAny(rest, "\n<"); end >= 0 {
+		rest = rest[:end]
+	}
+
+	return strings.TrimSpace(rest)
+}
@@ -0,0 +1,186 @@
+package verifier
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func init() {
+	RegisterBudgetDriver(genericBudgetDriver{})
+	RegisterBudgetDriver(ynabBudgetDriver{})
+	RegisterBudgetDriver(mintBudgetDriver{})
+}
+
+// genericBudgetDriver is the original budget export layout this tool was
+// built around: a single header row, then Date, (skip), Description, Details,
+// Amount in columns 0/2/3/4.
+type genericBudgetDriver struct{}
+
+func (genericBudgetDriver) Name() string { return "generic" }
+
+func (genericBudgetDriver) Detect(records [][]string) bool {
+	if len(records) == 0 {
+		return false
+	}
+
+	header := records[0]
+	return len(header) >= 5 && header[0] == "Date" && header[1] == "Num" && header[2] == "Payee" && header[3] == "Memo" && header[4] == "Amount"
+}
+
+func (genericBudgetDriver) Parse(records [][]string) ([]Transaction, error) {
+	transactions := []Transaction{}
+	for i := 1; i < len(records); i++ {
+		transaction, err := parseTransaction(records[i], 0, 2, 4, 3)
+		if err != nil {
+			log.Printf("invalid record, skipping: %+v", err)
+			continue
+		}
+
+		transactions = append(transactions, transaction)
+	}
+
+	return transactions, nil
+}
+
+// ynabBudgetDriver reads a YNAB "Register" export:
+// "Account,Flag,Date,Payee,Category Group/Category,Category Group,Category,Memo,Outflow,Inflow,Cleared".
+type ynabBudgetDriver struct{}
+
+func (ynabBudgetDriver) Name() string { return "ynab" }
+
+func (ynabBudgetDriver) Detect(records [][]string) bool {
+	if len(records) == 0 {
+		return false
+	}
+
+	header := records[0]
+	return len(header) >= 2 && header[0] == "Account" && header[1] == "Flag"
+}
+
+func (ynabBudgetDriver) Parse(records [][]string) ([]Transaction, error) {
+	transactions := []Transaction{}
+	for i := 1; i < len(records); i++ {
+		record := records[i]
+		if len(record) < 10 {
+			log.Printf("invalid record, skipping: %+v", record)
+			continue
+		}
+
+		transaction, err := parseYNABTransaction(record)
+		if err != nil {
+			log.Printf("invalid record, skipping: %+v", err)
+			continue
+		}
+
+		transactions = append(transactions, transaction)
+	}
+
+	return transactions, nil
+}
+
+// mintBudgetDriver reads a Mint "transactions.csv" export:
+// "Date,Description,Original Description,Amount,Transaction Type,Category,Account Name,Labels,Notes".
+type mintBudgetDriver struct{}
+
+func (mintBudgetDriver) Name() string { return "mint" }
+
+func (mintBudgetDriver) Detect(records [][]string) bool {
+	if len(records) == 0 {
+		return false
+	}
+
+	header := records[0]
+	return len(header) >= 5 && header[0] == "Date" && header[1] == "Description" && header[2] == "Original Description"
+}
+
+func (mintBudgetDriver) Parse(records [][]string) ([]Transaction, error) {
+	transactions := []Transaction{}
+	for i := 1; i < len(records); i++ {
+		record := records[i]
+		if len(record) < 5 {
+			log.Printf("invalid record, skipping: %+v", record)
+			continue
+		}
+
+		transaction, err := parseMintTransaction(record)
+		if err != nil {
+			log.Printf("invalid record, skipping: %+v", err)
+			continue
+		}
+
+		transactions = append(transactions, transaction)
+	}
+
+	return transactions, nil
+}
+
+// parseYNABTransaction parses a single YNAB register row. Unlike the
+// generic layout, YNAB splits the amount across separate Outflow and Inflow
+// columns rather than signing a single amount column.
+func parseYNABTransaction(record []string) (Transaction, error) {
+	refTime := "01/02/2006"
+	t, err := time.Parse(refTime, record[2])
+	if err != nil {
+		return Transaction{}, fmt.Errorf("invalid timestamp: %+v, %+v", err, record)
+	}
+
+	outflow, err := parseYNABAmount(record[8])
+	if err != nil {
+		return Transaction{}, fmt.Errorf("invalid outflow: %+v, %+v", err, record)
+	}
+	inflow, err := parseYNABAmount(record[9])
+	if err != nil {
+		return Transaction{}, fmt.Errorf("invalid inflow: %+v, %+v", err, record)
+	}
+
+	return Transaction{
+		Timestamp:   t,
+		Description: record[3],
+		Details:     record[7],
+		Amount:      inflow - outflow,
+	}, nil
+}
+
+func parseYNABAmount(field string) (int, error) {
+	field = strings.TrimSpace(field)
+	if field == "" {
+		return 0, nil
+	}
+
+	a, err := strconv.ParseFloat(strings.Replace(field, ",", "", -1), 64)
+	if err != nil {
+		return 0, err
+	}
+
+	return int(a * 100), nil
+}
+
+// parseMintTransaction parses a single Mint transactions.csv row. Mint signs
+// its Amount column via a separate Transaction Type column ("debit"/"credit")
+// instead of a leading minus sign.
+func parseMintTransaction(record []string) (Transaction, error) {
+	refTime := "01/02/2006"
+	t, err := time.Parse(refTime, record[0])
+	if err != nil {
+		return Transaction{}, fmt.Errorf("invalid timestamp: %+v, %+v", err, record)
+	}
+
+	a, err := strconv.ParseFloat(strings.Replace(record[3], ",", "", -1), 64)
+	if err != nil {
+		return Transaction{}, fmt.Errorf("invalid amount: %+v, %+v", err, record)
+	}
+
+	amount := int(a * 100)
+	if len(record) > 4 && record[4] == "debit" {
+		amount = -amount
+	}
+
+	return Transaction{
+		Timestamp:   t,
+		Description: record[1],
+		Amount:      amount,
+	}, nil
+}
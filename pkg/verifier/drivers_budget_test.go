@@ -0,0 +1,107 @@
+package verifier
+
+import "testing"
+
+// TestGenericBudgetDriverDetect guards against the generic driver's Detect
+// being loose enough to shadow ynabBudgetDriver/mintBudgetDriver: it must
+// only claim headers matching its own layout, not any 5+ column CSV.
+func TestGenericBudgetDriverDetect(t *testing.T) {
+	cases := []struct {
+		name   string
+		header []string
+		want   bool
+	}{
+		{"generic header", []string{"Date", "Num", "Payee", "Memo", "Amount"}, true},
+		{"ynab header", []string{"Account", "Flag", "Date", "Payee", "Category Group/Category", "Category Group", "Category", "Memo", "Outflow", "Inflow", "Cleared"}, false},
+		{"mint header", []string{"Date", "Description", "Original Description", "Amount", "Transaction Type", "Category", "Account Name", "Labels", "Notes"}, false},
+		{"empty", nil, false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var records [][]string
+			if c.header != nil {
+				records = [][]string{c.header}
+			}
+
+			if got := (genericBudgetDriver{}).Detect(records); got != c.want {
+				t.Errorf("Detect(%v) = %v, want %v", c.header, got, c.want)
+			}
+		})
+	}
+}
+
+func TestYNABBudgetDriverDetect(t *testing.T) {
+	cases := []struct {
+		name   string
+		header []string
+		want   bool
+	}{
+		{"ynab header", []string{"Account", "Flag", "Date", "Payee", "Category Group/Category", "Category Group", "Category", "Memo", "Outflow", "Inflow", "Cleared"}, true},
+		{"generic header", []string{"Date", "Num", "Payee", "Memo", "Amount"}, false},
+		{"mint header", []string{"Date", "Description", "Original Description", "Amount", "Transaction Type", "Category", "Account Name", "Labels", "Notes"}, false},
+		{"empty", nil, false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var records [][]string
+			if c.header != nil {
+				records = [][]string{c.header}
+			}
+
+			if got := (ynabBudgetDriver{}).Detect(records); got != c.want {
+				t.Errorf("Detect(%v) = %v, want %v", c.header, got, c.want)
+			}
+		})
+	}
+}
+
+func TestMintBudgetDriverDetect(t *testing.T) {
+	cases := []struct {
+		name   string
+		header []string
+		want   bool
+	}{
+		{"mint header", []string{"Date", "Description", "Original Description", "Amount", "Transaction Type", "Category", "Account Name", "Labels", "Notes"}, true},
+		{"generic header", []string{"Date", "Num", "Payee", "Memo", "Amount"}, false},
+		{"ynab header", []string{"Account", "Flag", "Date", "Payee", "Category Group/Category", "Category Group", "Category", "Memo", "Outflow", "Inflow", "Cleared"}, false},
+		{"empty", nil, false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var records [][]string
+			if c.header != nil {
+				records = [][]string{c.header}
+			}
+
+			if got := (mintBudgetDriver{}).Detect(records); got != c.want {
+				t.Errorf("Detect(%v) = %v, want %v", c.header, got, c.want)
+			}
+		})
+	}
+}
+
+// TestFindBudgetDriverAutoPrefersMostSpecific is the regression the review
+// flagged directly: auto-detecting a Mint (or YNAB) export must not fall
+// through to the generic driver just because generic registers first.
+func TestFindBudgetDriverAutoPrefersMostSpecific(t *testing.T) {
+	mintHeader := [][]string{{"Date", "Description", "Original Description", "Amount", "Transaction Type", "Category", "Account Name", "Labels", "Notes"}}
+	driver, err := findBudgetDriver("auto", mintHeader)
+	if err != nil {
+		t.Fatalf("findBudgetDriver failed: %+v", err)
+	}
+	if driver.Name() != "mint" {
+		t.Errorf("auto-detected driver = %q, want %q", driver.Name(), "mint")
+	}
+
+	ynabHeader := [][]string{{"Account", "Flag", "Date", "Payee", "Category Group/Category", "Category Group", "Category", "Memo", "Outflow", "Inflow", "Cleared"}}
+	driver, err = findBudgetDriver("auto", ynabHeader)
+	if err != nil {
+		t.Fatalf("findBudgetDriver failed: %+v", err)
+	}
+	if driver.Name() != "ynab" {
+		t.Errorf("auto-detected driver = %q, want %q", driver.Name(), "ynab")
+	}
+}
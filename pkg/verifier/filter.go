@@ -0,0 +1,260 @@
+package verifier
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"regexp"
+	"time"
+)
+
+// filterDateLayout is the date format filter.json uses for date_before and
+// date_after, distinct from the "01/02/2006" layout bank/budget exports use.
+const filterDateLayout = "2006-01-02"
+
+// Filter is the filter.json schema: a rule matched against a transaction,
+// plus an Action saying what to do once it matches. Rules are evaluated in
+// file order and later matches override earlier ones (the same "last rule
+// wins" semantics gitignore and firewall ACLs use), so an include rule can
+// carve exceptions out of an earlier, broader exclude rule.
+type Filter struct {
+	FilterRegex string `json:"regex"`
+	MinAmount   int    `json:"min"` // amount in cents, can be negative or positive
+	MaxAmount   int    `json:"max"` // amount in cents, can be negative or positive
+
+	// Field is which part of the transaction FilterRegex is matched
+	// against: "description" (the default), "details", or "either".
+	Field string `json:"field,omitempty"`
+	// Action is "exclude" (the default, and the original behavior of this
+	// package) or "include", to whitelist transactions a broader exclude
+	// rule earlier in the file would otherwise catch.
+	Action string `json:"action,omitempty"`
+	// DateBefore and DateAfter, if set, are "2006-01-02" dates bounding
+	// which transactions this rule applies to.
+	DateBefore string `json:"date_before,omitempty"`
+	DateAfter  string `json:"date_after,omitempty"`
+	// And holds nested clauses that must all also match for this rule to
+	// match, letting a single rule combine several conditions.
+	And []Filter `json:"and,omitempty"`
+}
+
+// String formats f for the CLI: `filter list` and `--dry-run-filters` both
+// print it, so it needs to carry enough of f to tell two rules apart, not
+// just the regex/min/max the original single-field filter had.
+func (f Filter) String() string {
+	field := f.Field
+	if field == "" {
+		field = "description"
+	}
+	action := f.Action
+	if action == "" {
+		action = "exclude"
+	}
+
+	s := fmt.Sprintf("[filter:'%s', field:%s, action:%s, min:%s, max:%s", f.FilterRegex, field, action, FormatAmount(f.MinAmount), FormatAmount(f.MaxAmount))
+
+	if f.DateAfter != "" {
+		s += fmt.Sprintf(", after:%s", f.DateAfter)
+	}
+	if f.DateBefore != "" {
+		s += fmt.Sprintf(", before:%s", f.DateBefore)
+	}
+	if len(f.And) > 0 {
+		s += fmt.Sprintf(", and:%d clauses", len(f.And))
+	}
+
+	return s + "]"
+}
+
+// ID returns a stable identifier for f, derived from its fields. It's used to
+// address a specific filter over HTTP, e.g. `DELETE /filters/{id}`.
+func (f Filter) ID() string {
+	h := sha1.New()
+	buf, _ := json.Marshal(f)
+	h.Write(buf)
+	return hex.EncodeToString(h.Sum(nil))[:12]
+}
+
+// LoadFilters reads and parses the filter file at filterPath.
+func LoadFilters(filterPath string) ([]Filter, error) {
+	buf, err := ioutil.ReadFile(filterPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read filter file: %+v", err)
+	}
+
+	var filters []Filter
+	err = json.Unmarshal(buf, &filters)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal filter file: %+v", err)
+	}
+
+	return filters, nil
+}
+
+// TryLoadFilters behaves like LoadFilters, except a missing filter file is
+// treated as an empty filter set rather than an error.
+func TryLoadFilters(filterPath string) ([]Filter, error) {
+	if _, err := os.Stat(filterPath); os.IsNotExist(err) {
+		return []Filter{}, nil
+	}
+
+	return LoadFilters(filterPath)
+}
+
+// SaveFilters writes filters to filterPath as indented JSON.
+func SaveFilters(filterPath string, filters []Filter) error {
+	buf, err := json.MarshalIndent(filters, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal filters: %+v", err)
+	}
+
+	if err := ioutil.WriteFile(filterPath, buf, 0644); err != nil {
+		return fmt.Errorf("failed to write filter file %s: %+v", filterPath, err)
+	}
+
+	return nil
+}
+
+// SelectFunc reports whether t matches some condition. It mirrors restic's
+// pipe.SelectFunc: a rule is just a predicate, and rules compose by calling
+// into one another rather than by re-walking a slice of configuration.
+type SelectFunc func(t *Transaction) bool
+
+// rule is a Filter compiled into a SelectFunc once, at load time, instead of
+// recompiling its regex (and silently discarding compile errors) on every
+// transaction the way the original isFiltered did.
+type rule struct {
+	source Filter
+	action string
+	match  SelectFunc
+}
+
+// FilterChain is a sequence of compiled Filters, ready to be applied to a
+// transaction with Select.
+type FilterChain struct {
+	rules []rule
+}
+
+// CompileFilters compiles filters into a FilterChain, resolving every regex
+// and date bound up front so bad filter.json entries are reported once,
+// here, rather than once per transaction.
+func CompileFilters(filters []Filter) (FilterChain, error) {
+	chain := FilterChain{rules: make([]rule, 0, len(filters))}
+
+	for i := range filters {
+		r, err := compileRule(filters[i])
+		if err != nil {
+			return FilterChain{}, fmt.Errorf("filter %d: %+v", i, err)
+		}
+
+		chain.rules = append(chain.rules, r)
+	}
+
+	return chain, nil
+}
+
+func compileRule(f Filter) (rule, error) {
+	match, err := compileSelectFunc(f)
+	if err != nil {
+		return rule{}, err
+	}
+
+	action := f.Action
+	if action == "" {
+		action = "exclude"
+	}
+	if action != "exclude" && action != "include" {
+		return rule{}, fmt.Errorf("invalid action %q, must be \"exclude\" or \"include\"", action)
+	}
+
+	return rule{source: f, action: action, match: match}, nil
+}
+
+// compileSelectFunc builds the SelectFunc for a single Filter, ANDing
+// together its regex, amount bounds, date window, and any nested clauses.
+// The regex and amount bounds are always part of the result (an empty regex
+// matches every description, same as regexp.MatchString("", ...) always
+// did), so a bare Filter{} keeps matching exactly what it always has.
+func compileSelectFunc(f Filter) (SelectFunc, error) {
+	re, err := regexp.Compile(f.FilterRegex)
+	if err != nil {
+		return nil, fmt.Errorf("invalid regex %q: %+v", f.FilterRegex, err)
+	}
+
+	field := f.Field
+	if field == "" {
+		field = "description"
+	}
+
+	funcs := []SelectFunc{}
+	switch field {
+	case "description":
+		funcs = append(funcs, func(t *Transaction) bool { return re.MatchString(t.Description) })
+	case "details":
+		funcs = append(funcs, func(t *Transaction) bool { return re.MatchString(t.Details) })
+	case "either":
+		funcs = append(funcs, func(t *Transaction) bool { return re.MatchString(t.Description) || re.MatchString(t.Details) })
+	default:
+		return nil, fmt.Errorf("invalid field %q, must be \"description\", \"details\", or \"either\"", field)
+	}
+
+	min, max := f.MinAmount, f.MaxAmount
+	funcs = append(funcs, func(t *Transaction) bool { return t.Amount >= min && t.Amount <= max })
+
+	if f.DateAfter != "" {
+		after, err := time.Parse(filterDateLayout, f.DateAfter)
+		if err != nil {
+			return nil, fmt.Errorf("invalid date_after %q: %+v", f.DateAfter, err)
+		}
+		funcs = append(funcs, func(t *Transaction) bool { return t.Timestamp.After(after) })
+	}
+
+	if f.DateBefore != "" {
+		before, err := time.Parse(filterDateLayout, f.DateBefore)
+		if err != nil {
+			return nil, fmt.Errorf("invalid date_before %q: %+v", f.DateBefore, err)
+		}
+		funcs = append(funcs, func(t *Transaction) bool { return t.Timestamp.Before(before) })
+	}
+
+	for i := range f.And {
+		nested, err := compileSelectFunc(f.And[i])
+		if err != nil {
+			return nil, fmt.Errorf("and[%d]: %+v", i, err)
+		}
+		funcs = append(funcs, nested)
+	}
+
+	return andSelectFuncs(funcs), nil
+}
+
+// andSelectFuncs composes funcs into a single SelectFunc that matches only
+// when every one of them does. An empty funcs list matches everything.
+func andSelectFuncs(funcs []SelectFunc) SelectFunc {
+	return func(t *Transaction) bool {
+		for _, f := range funcs {
+			if !f(t) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// Select applies every rule in the chain to t in order, returning whether t
+// ends up excluded and the rule that last decided its fate, whether it
+// excluded or included t (nil if no rule matched; used by
+// `--dry-run-filters`).
+func (fc FilterChain) Select(t *Transaction) (excluded bool, matched *Filter) {
+	for i := range fc.rules {
+		if fc.rules[i].match(t) {
+			excluded = fc.rules[i].action == "exclude"
+			matched = &fc.rules[i].source
+		}
+	}
+
+	return excluded, matched
+}
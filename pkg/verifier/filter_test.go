@@ -0,0 +1,41 @@
+package verifier
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestFilterStringSurfacesNonRegexFields guards against String() silently
+// dropping Field/Action/date bounds/nested clauses, which made `filter list`
+// and `--dry-run-filters` show an include rule identically to an exclude
+// rule.
+func TestFilterStringSurfacesNonRegexFields(t *testing.T) {
+	f := Filter{
+		FilterRegex: "rent",
+		Field:       "either",
+		Action:      "include",
+		DateAfter:   "2020-01-01",
+		DateBefore:  "2020-12-31",
+		And:         []Filter{{FilterRegex: "deposit"}},
+	}
+
+	s := f.String()
+	for _, want := range []string{"either", "include", "2020-01-01", "2020-12-31", "1 clauses"} {
+		if !strings.Contains(s, want) {
+			t.Errorf("String() = %q, want it to contain %q", s, want)
+		}
+	}
+}
+
+// TestFilterStringDefaults checks the zero-value Field/Action ("description"
+// and "exclude") still show up, so a bare Filter{} doesn't print blank.
+func TestFilterStringDefaults(t *testing.T) {
+	f := Filter{FilterRegex: "rent"}
+
+	s := f.String()
+	for _, want := range []string{"description", "exclude"} {
+		if !strings.Contains(s, want) {
+			t.Errorf("String() = %q, want it to contain %q", s, want)
+		}
+	}
+}
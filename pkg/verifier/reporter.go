@@ -0,0 +1,113 @@
+package verifier
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// EventType discriminates the kind of record a Reporter receives.
+type EventType string
+
+const (
+	EventMissing   EventType = "missing"
+	EventMatched   EventType = "matched"
+	EventFiltered  EventType = "filtered"
+	EventAmbiguous EventType = "ambiguous"
+)
+
+// Event is one structured record produced while CompareTransactions makes its
+// decisions: a transaction got filtered out, a bank item had more than one
+// candidate match, a transaction got matched, or a transaction came up
+// missing.
+type Event struct {
+	Type            EventType `json:"event"`
+	Timestamp       time.Time `json:"timestamp"`
+	Description     string    `json:"description"`
+	Details         string    `json:"details"`
+	AmountCents     int       `json:"amount_cents"`
+	MatchedBudgetID string    `json:"matched_budget_id,omitempty"`
+	MatchedFilterID string    `json:"matched_filter_id,omitempty"`
+	Candidates      int       `json:"candidates,omitempty"`
+}
+
+// Reporter receives Events as CompareTransactions makes its decisions. A nil
+// Reporter is valid and simply discards every event.
+type Reporter interface {
+	Report(Event)
+}
+
+func eventFor(t Transaction, eventType EventType) Event {
+	return Event{
+		Type:        eventType,
+		Timestamp:   t.Timestamp,
+		Description: t.Description,
+		Details:     t.Details,
+		AmountCents: t.Amount,
+	}
+}
+
+func report(r Reporter, e Event) {
+	if r != nil {
+		r.Report(e)
+	}
+}
+
+// NDJSONReporter writes each Event to W as a single line of JSON, for
+// `--output=ndjson`.
+type NDJSONReporter struct {
+	W io.Writer
+}
+
+func (r NDJSONReporter) Report(e Event) {
+	buf, err := json.Marshal(e)
+	if err != nil {
+		return
+	}
+
+	fmt.Fprintln(r.W, string(buf))
+}
+
+// CollectingReporter accumulates every Event it sees, for `--output=json`
+// where the whole stream is emitted as a single array once comparison
+// finishes.
+type CollectingReporter struct {
+	Events []Event
+}
+
+func (r *CollectingReporter) Report(e Event) {
+	r.Events = append(r.Events, e)
+}
+
+// TextReporter writes human-readable diagnostics to W, the way
+// CompareTransactions used to via the log package, minus the timestamp
+// prefix. Filtered, ambiguous-match, and matched events are only written
+// when Verbose; missing transactions are always written.
+type TextReporter struct {
+	W       io.Writer
+	Verbose bool
+}
+
+func (r TextReporter) Report(e Event) {
+	switch e.Type {
+	case EventFiltered:
+		if r.Verbose {
+			fmt.Fprintf(r.W, "filtered %s by rule %s\n", r.describe(e), e.MatchedFilterID)
+		}
+	case EventAmbiguous:
+		if r.Verbose {
+			fmt.Fprintf(r.W, "bank item %s has %d potential matches\n", r.describe(e), e.Candidates)
+		}
+	case EventMatched:
+		if r.Verbose {
+			fmt.Fprintf(r.W, "bank item %s matched with budget entry %s\n", r.describe(e), e.MatchedBudgetID)
+		}
+	case EventMissing:
+		fmt.Fprintf(r.W, "missing %s\n", r.describe(e))
+	}
+}
+
+func (r TextReporter) describe(e Event) string {
+	return fmt.Sprintf("[%s: '%s', '%s', %s]", e.Timestamp.Format("2006-01-02"), e.Description, e.Details, FormatAmount(e.AmountCents))
+}
@@ -0,0 +1,285 @@
+// Package verifier holds the reusable core of budget-verifier: parsing bank
+// and budget exports into Transactions, loading Filters, and matching the
+// two sides up against each other. The CLI and the HTTP server are both thin
+// wrappers around this package.
+package verifier
+
+import (
+	"crypto/sha1"
+	"encoding/csv"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// FilterFileName is the default filter file name, resolved relative to
+	// the current directory unless overridden.
+	FilterFileName = `filter.json`
+)
+
+// Options controls how CompareTransactions matches bank and budget entries.
+type Options struct {
+	// DateMatchRangeDays bounds how far a budget entry may lead a bank entry
+	// and still count as a match: the budget entry must be dated on or before
+	// the bank entry, and fewer than this many days before it.
+	DateMatchRangeDays int
+	// Verbose is not read by CompareTransactions; which decisions get
+	// written is up to the Reporter passed to it (see TextReporter.Verbose).
+	Verbose bool
+}
+
+// DefaultOptions mirrors the thresholds this package has always used.
+func DefaultOptions() Options {
+	return Options{DateMatchRangeDays: 7}
+}
+
+type Transaction struct {
+	Timestamp   time.Time
+	Description string
+	Details     string
+	Amount      int // amount in cents, can be negative or positive
+	Matching    *Transaction
+}
+
+// ID returns a stable identifier for t, derived from its fields. It's used to
+// address a specific transaction from the command line or over HTTP, e.g.
+// `explain <id>`.
+func (t Transaction) ID() string {
+	h := sha1.New()
+	fmt.Fprintf(h, "%s|%s|%s|%d", t.Timestamp.Format(time.RFC3339), t.Description, t.Details, t.Amount)
+	return hex.EncodeToString(h.Sum(nil))[:12]
+}
+
+func (t Transaction) String() string {
+	var matchingStr string
+	if t.Matching != nil {
+		matchingStr = t.Matching.StringNoFollow()
+	} else {
+		matchingStr = "<nil>"
+	}
+
+	return fmt.Sprintf("[%s (matching: %s)]", t.StringNoFollow(), matchingStr)
+}
+
+func (t Transaction) StringNoFollow() string {
+	return fmt.Sprintf(
+		"[%s: '%s', '%s', %s]",
+		t.Timestamp.Format("2006-01-02"),
+		t.Description,
+		t.Details,
+		FormatAmount(t.Amount))
+}
+
+// LoadBankTransactions reads path and parses it with the bank driver named by
+// format ("auto" to detect).
+func LoadBankTransactions(path, format string) ([]Transaction, error) {
+	records, err := readFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %+v", path, err)
+	}
+
+	driver, err := findBankDriver(format, records)
+	if err != nil {
+		return nil, fmt.Errorf("failed to select bank driver for %s: %+v", path, err)
+	}
+
+	return driver.Parse(records)
+}
+
+// LoadBudgetTransactions reads path and parses it with the budget driver
+// named by format ("auto" to detect).
+func LoadBudgetTransactions(path, format string) ([]Transaction, error) {
+	records, err := readFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %+v", path, err)
+	}
+
+	driver, err := findBudgetDriver(format, records)
+	if err != nil {
+		return nil, fmt.Errorf("failed to select budget driver for %s: %+v", path, err)
+	}
+
+	return driver.Parse(records)
+}
+
+// ParseBankCSV parses r with the bank driver named by format ("auto" to
+// detect), without requiring the data to live on disk. Used by the HTTP
+// server to accept uploaded exports.
+func ParseBankCSV(r io.Reader, format string) ([]Transaction, error) {
+	records, err := readRecords(r)
+	if err != nil {
+		return nil, err
+	}
+
+	driver, err := findBankDriver(format, records)
+	if err != nil {
+		return nil, fmt.Errorf("failed to select bank driver: %+v", err)
+	}
+
+	return driver.Parse(records)
+}
+
+// ParseBudgetCSV is ParseBankCSV's counterpart for budget exports.
+func ParseBudgetCSV(r io.Reader, format string) ([]Transaction, error) {
+	records, err := readRecords(r)
+	if err != nil {
+		return nil, err
+	}
+
+	driver, err := findBudgetDriver(format, records)
+	if err != nil {
+		return nil, fmt.Errorf("failed to select budget driver: %+v", err)
+	}
+
+	return driver.Parse(records)
+}
+
+func readFile(p string) ([][]string, error) {
+	f, err := os.Open(p)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %+v", p, err)
+	}
+	defer f.Close()
+
+	return readRecords(f)
+}
+
+func readRecords(r io.Reader) ([][]string, error) {
+	records := [][]string{}
+	csvReader := csv.NewReader(r)
+	csvReader.FieldsPerRecord = -1
+
+	for {
+		fields, err := csvReader.Read()
+		if err != nil {
+			if err == io.EOF {
+				return records, nil
+			}
+
+			return nil, fmt.Errorf("Read error: %+v", err)
+		}
+
+		records = append(records, fields)
+	}
+}
+
+// parseTransaction is the shared row parser for drivers whose layout is a
+// fixed set of column indices (most CSV-based bank and budget exports).
+// detailsIndex <= 0 means the format has no separate details column.
+func parseTransaction(record []string, timestampIndex, descriptionIndex, amountIndex, detailsIndex int) (Transaction, error) {
+	refTime := "01/02/2006"
+	t, err := time.Parse(refTime, record[timestampIndex])
+	if err != nil {
+		return Transaction{}, fmt.Errorf("invalid timestamp: %+v, %+v", err, record)
+	}
+
+	a, err := strconv.ParseFloat(strings.Replace(record[amountIndex], ",", "", -1), 64)
+	if err != nil {
+		return Transaction{}, fmt.Errorf("invalid amount: %+v, %+v", err, record)
+	}
+
+	var d string
+	if detailsIndex > 0 {
+		d = record[detailsIndex]
+	}
+
+	transaction := Transaction{
+		Timestamp:   t,
+		Description: record[descriptionIndex],
+		Details:     d,
+		Amount:      (int)(a * 100),
+	}
+
+	return transaction, nil
+}
+
+// CompareTransactions matches bankTransactions against budgetTransactions,
+// filtering bank entries through filterChain first, and returns the bank
+// transactions that have no matching budget entry. Matched transactions have
+// their Matching field set on both sides; this mutates the input slices.
+// Every decision is also sent to reporter, which may be nil.
+func CompareTransactions(bankTransactions, budgetTransactions []Transaction, filterChain FilterChain, opts Options, reporter Reporter) ([]Transaction, error) {
+	missingTransactions := []Transaction{}
+
+	for bankIndex := 0; bankIndex < len(bankTransactions); bankIndex++ {
+		bankT := &(bankTransactions[bankIndex])
+
+		if excluded, matched := filterChain.Select(bankT); excluded {
+			e := eventFor(*bankT, EventFiltered)
+			e.MatchedFilterID = matched.ID()
+			report(reporter, e)
+			continue
+		}
+
+		potentialMatches := []*Transaction{}
+		for budgetIndex := 0; budgetIndex < len(budgetTransactions); budgetIndex++ {
+			budgetT := &(budgetTransactions[budgetIndex])
+			if bankT.Amount == budgetT.Amount {
+				if budgetT.Matching != nil {
+					// this budget entry has already been matched, it can't be matched again
+					continue
+				}
+
+				// the amount matches and this budget entry hasn't already been matched yet.  add to the list
+				// of potential matches so we can later on pick the closest match by date
+				potentialMatches = append(potentialMatches, budgetT)
+			}
+		}
+
+		var closest *Transaction
+		closestDuration := 99999.0
+		if len(potentialMatches) > 0 {
+			if len(potentialMatches) > 1 {
+				e := eventFor(*bankT, EventAmbiguous)
+				e.Candidates = len(potentialMatches)
+				report(reporter, e)
+			}
+
+			for i := 0; i < len(potentialMatches); i++ {
+				pm := potentialMatches[i]
+				d := bankT.Timestamp.Sub(pm.Timestamp).Hours()
+
+				// for the best match, the delta between bank statement item and budget app item should always
+				// be 0 or positive.  The budget app entry is always from the date the transaction happened, while
+				// the bank item takes a while to clear.  Bank should always be later than budget app.
+				if d >= 0 && d < closestDuration {
+					closestDuration = d
+					closest = pm
+				}
+			}
+
+			// verify the date of the closest matching budget transaction is close enough in time
+			// (don't match transactions with the same amount but from very different dates)
+			if closest != nil &&
+				closest.Timestamp.Before(bankT.Timestamp.AddDate(0, 0, opts.DateMatchRangeDays)) &&
+				closest.Timestamp.After(bankT.Timestamp.AddDate(0, 0, -1*opts.DateMatchRangeDays)) {
+
+				bankT.Matching = closest
+				closest.Matching = bankT
+
+				e := eventFor(*bankT, EventMatched)
+				e.MatchedBudgetID = closest.ID()
+				report(reporter, e)
+			}
+		}
+
+		if bankT.Matching == nil {
+			missingTransactions = append(missingTransactions, *bankT)
+		}
+	}
+
+	for _, t := range missingTransactions {
+		report(reporter, eventFor(t, EventMissing))
+	}
+
+	return missingTransactions, nil
+}
+
+func FormatAmount(amount int) string {
+	return strconv.FormatFloat(float64(amount)/100.0, 'f', 2, 64)
+}